@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// tokenTTL is how long an issued access token remains valid.
+const tokenTTL = 24 * time.Hour
+
+// claims embeds the registered "sub" claim we read back out as the user id.
+type claims struct {
+	jwt.RegisteredClaims
+}
+
+// NewToken signs a JWT identifying userID, using secret as the HMAC key.
+func NewToken(secret []byte, userID string) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(tokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	})
+	return token.SignedString(secret)
+}
+
+// ParseToken validates tokenString against secret and returns the user id
+// from its subject claim.
+func ParseToken(secret []byte, tokenString string) (string, error) {
+	var c claims
+	_, err := jwt.ParseWithClaims(tokenString, &c, func(t *jwt.Token) (interface{}, error) {
+		return secret, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return c.Subject, nil
+}
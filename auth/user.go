@@ -0,0 +1,106 @@
+// Package auth handles user registration, login, and the JWT middleware
+// that protects write endpoints. Users are stored as :User nodes in the
+// same graph the rest of the app queries, rather than a separate store.
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// ErrEmailTaken is returned by Register when a :User with that email already exists.
+var ErrEmailTaken = errors.New("auth: email already registered")
+
+// ErrInvalidCredentials is returned by Login when the email is unknown or
+// the password doesn't match. It deliberately doesn't distinguish the two.
+var ErrInvalidCredentials = errors.New("auth: invalid email or password")
+
+// User is the public view of a :User node.
+type User struct {
+	ID    string `json:"id"`
+	Email string `json:"email"`
+}
+
+// EnsureConstraints creates the uniqueness constraint Register relies on to
+// reject duplicate emails atomically. Call it once at startup.
+func EnsureConstraints(ctx context.Context, driver neo4j.DriverWithContext, database string) error {
+	_, err := neo4j.ExecuteQuery(ctx, driver,
+		`CREATE CONSTRAINT user_email_unique IF NOT EXISTS FOR (u:User) REQUIRE u.email IS UNIQUE`,
+		nil,
+		neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(database))
+	return err
+}
+
+// Register creates a new :User node with a bcrypt-hashed password and
+// returns its public view.
+//
+// When constraintEnsured is true (EnsureConstraints succeeded at startup),
+// the user_email_unique constraint alone rejects duplicate emails
+// atomically, so two concurrent registrations for the same email can't both
+// succeed. When it's false - e.g. a read-only connection that can't create
+// constraints - Register falls back to a check-then-create query. That
+// fallback is racy under true concurrency, but it's the same protection the
+// app had before the constraint existed, so a deployment without it is no
+// worse off than it always was.
+func Register(ctx context.Context, driver neo4j.DriverWithContext, database, email, password string, constraintEnsured bool) (*User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, fmt.Errorf("hashing password: %w", err)
+	}
+
+	if !constraintEnsured {
+		result, err := neo4j.ExecuteQuery(ctx, driver, `MATCH (existing:User {email: $email}) RETURN existing.id AS id`,
+			map[string]interface{}{"email": email},
+			neo4j.EagerResultTransformer,
+			neo4j.ExecuteQueryWithDatabase(database))
+		if err != nil {
+			return nil, fmt.Errorf("checking existing user: %w", err)
+		}
+		if len(result.Records) > 0 {
+			return nil, ErrEmailTaken
+		}
+	}
+
+	result, err := neo4j.ExecuteQuery(ctx, driver, `CREATE (u:User {id: randomUUID(), email: $email, passwordHash: $hash, createdAt: datetime()})
+			RETURN u.id AS id`,
+		map[string]interface{}{"email": email, "hash": string(hash)},
+		neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(database))
+	if err != nil {
+		var neo4jErr *neo4j.Neo4jError
+		if errors.As(err, &neo4jErr) && strings.Contains(neo4jErr.Code, "ConstraintValidationFailed") {
+			return nil, ErrEmailTaken
+		}
+		return nil, fmt.Errorf("creating user: %w", err)
+	}
+	userID, _, _ := neo4j.GetRecordValue[string](result.Records[0], "id")
+	return &User{ID: userID, Email: email}, nil
+}
+
+// Login verifies email/password against the stored :User node and returns
+// its public view on success.
+func Login(ctx context.Context, driver neo4j.DriverWithContext, database, email, password string) (*User, error) {
+	result, err := neo4j.ExecuteQuery(ctx, driver, `MATCH (u:User {email: $email}) RETURN u.id AS id, u.passwordHash AS hash`,
+		map[string]interface{}{"email": email},
+		neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(database))
+	if err != nil {
+		return nil, fmt.Errorf("looking up user: %w", err)
+	}
+	if len(result.Records) == 0 {
+		return nil, ErrInvalidCredentials
+	}
+
+	id, _, _ := neo4j.GetRecordValue[string](result.Records[0], "id")
+	hash, _, _ := neo4j.GetRecordValue[string](result.Records[0], "hash")
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		return nil, ErrInvalidCredentials
+	}
+	return &User{ID: id, Email: email}, nil
+}
@@ -0,0 +1,73 @@
+// Package cache wraps read handlers with a Redis-backed response cache,
+// modeled on the Redis caching pattern used by the collabyt example: cache
+// key -> raw JSON body, with a TTL and explicit invalidation on writes.
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Cache wraps a Redis client with the handful of operations the HTTP layer
+// needs: read-through get/set for handler bodies and invalidation on write.
+type Cache struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// Configuration holds the CACHE_* environment variables.
+type Configuration struct {
+	Host     string
+	Port     string
+	Password string
+	TTL      time.Duration
+}
+
+// New connects to Redis using cfg and returns a Cache ready to wrap handlers.
+func New(cfg Configuration) *Cache {
+	client := redis.NewClient(&redis.Options{
+		Addr:     fmt.Sprintf("%s:%s", cfg.Host, cfg.Port),
+		Password: cfg.Password,
+	})
+	return &Cache{client: client, ttl: cfg.TTL}
+}
+
+// Get returns the cached body for key, or ok=false on a miss.
+func (c *Cache) Get(ctx context.Context, key string) (body []byte, ok bool) {
+	body, err := c.client.Get(ctx, key).Bytes()
+	if err != nil {
+		return nil, false
+	}
+	return body, true
+}
+
+// Set stores body under key with the configured TTL.
+func (c *Cache) Set(ctx context.Context, key string, body []byte) error {
+	return c.client.Set(ctx, key, body, c.ttl).Err()
+}
+
+// Del removes every given key. Missing keys are ignored.
+func (c *Cache) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// DelPrefix removes every key starting with prefix, e.g. all cached /graph
+// entries regardless of which limit they were served for.
+func (c *Cache) DelPrefix(ctx context.Context, prefix string) error {
+	keys, err := c.client.Keys(ctx, prefix+"*").Result()
+	if err != nil {
+		return err
+	}
+	return c.Del(ctx, keys...)
+}
+
+// Flush drops every key the cache currently holds.
+func (c *Cache) Flush(ctx context.Context) error {
+	return c.client.FlushDB(ctx).Err()
+}
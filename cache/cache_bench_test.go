@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// BenchmarkGraphHandler_Uncached simulates the cost of recomputing the
+// /graph?limit=50 payload on every request.
+func BenchmarkGraphHandler_Uncached(b *testing.B) {
+	handler := slowGraphHandler()
+	req := httptest.NewRequest(http.MethodGet, "/graph?limit=50", nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler(httptest.NewRecorder(), req)
+	}
+}
+
+// BenchmarkGraphHandler_Cached shows the latency reduction once Wrap serves
+// repeated /graph?limit=50 requests out of Redis instead of recomputing.
+func BenchmarkGraphHandler_Cached(b *testing.B) {
+	mr, err := miniredis.Run()
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer mr.Close()
+
+	c := New(Configuration{Host: mr.Host(), Port: mr.Port(), TTL: time.Minute})
+	handler := c.Wrap(slowGraphHandler(), graphBenchKey)
+	req := httptest.NewRequest(http.MethodGet, "/graph?limit=50", nil)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		handler(httptest.NewRecorder(), req)
+	}
+}
+
+func graphBenchKey(req *http.Request) string {
+	return "graph:" + req.URL.Query().Get("limit")
+}
+
+// slowGraphHandler stands in for graphHandler's Cypher round trip without
+// pulling the main package (and its neo4j driver dependency) into this
+// package's tests.
+func slowGraphHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"nodes":[],"links":[]}`))
+	}
+}
@@ -0,0 +1,75 @@
+package cache
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+)
+
+// KeyFunc derives a cache key from a request. Callers normalize whatever
+// parameters matter to the handler (e.g. lowercased title, limit) so that
+// equivalent requests share a cache entry.
+type KeyFunc func(req *http.Request) string
+
+// Wrap serves GET requests out of the cache when possible, otherwise runs
+// next and stores its response body for subsequent requests. On every request
+// it looks up in the cache it sets an X-Cache: HIT|MISS header so callers can
+// see which path was taken; requests it passes straight to next (non-GET and
+// NDJSON) get no X-Cache header.
+//
+// It never caches a streamed response (Accept: application/x-ndjson):
+// buffering the whole thing in bodyRecorder to cache it would defeat the
+// point of streaming, and a cache hit would have to guess at the right
+// Content-Type rather than reuse whatever next would have written.
+func (c *Cache) Wrap(next http.HandlerFunc, keyFn KeyFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet || strings.Contains(req.Header.Get("Accept"), "application/x-ndjson") {
+			next(w, req)
+			return
+		}
+
+		key := keyFn(req)
+		if body, ok := c.Get(req.Context(), key); ok {
+			w.Header().Set("X-Cache", "HIT")
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write(body)
+			return
+		}
+
+		w.Header().Set("X-Cache", "MISS")
+		recorder := &bodyRecorder{ResponseWriter: w, buf: &bytes.Buffer{}}
+		next(recorder, req)
+
+		if recorder.wrote && recorder.status == http.StatusOK {
+			_ = c.Set(req.Context(), key, recorder.buf.Bytes())
+		}
+	}
+}
+
+// bodyRecorder captures a handler's response body so it can be cached after
+// the fact, while still forwarding the write to the real ResponseWriter.
+// wrote distinguishes a handler that actually produced a response from one
+// that returned early without calling WriteHeader or Write at all (e.g. on a
+// query error) - those must not be cached, even though net/http reports
+// such an empty response to the client as an implicit 200.
+type bodyRecorder struct {
+	http.ResponseWriter
+	buf    *bytes.Buffer
+	status int
+	wrote  bool
+}
+
+func (r *bodyRecorder) WriteHeader(status int) {
+	r.status = status
+	r.wrote = true
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *bodyRecorder) Write(b []byte) (int, error) {
+	if !r.wrote {
+		r.status = http.StatusOK
+		r.wrote = true
+	}
+	r.buf.Write(b)
+	return r.ResponseWriter.Write(b)
+}
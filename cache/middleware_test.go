@@ -0,0 +1,88 @@
+package cache
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestCache(t *testing.T) *Cache {
+	t.Helper()
+	mr, err := miniredis.Run()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(mr.Close)
+	return New(Configuration{Host: mr.Host(), Port: mr.Port(), TTL: time.Minute})
+}
+
+// TestWrap_DoesNotCacheHandlerErrors guards against a handler that returns
+// without calling WriteHeader or Write at all (the pattern every query-error
+// branch in server.go uses) getting its empty body cached and replayed as a
+// 200 on every subsequent request.
+func TestWrap_DoesNotCacheHandlerErrors(t *testing.T) {
+	c := newTestCache(t)
+	calls := 0
+	handler := c.Wrap(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		// Simulates a query error: log and return, no WriteHeader/Write.
+	}, func(req *http.Request) string { return "key" })
+
+	req := httptest.NewRequest(http.MethodGet, "/graph", nil)
+	handler(httptest.NewRecorder(), req)
+	handler(httptest.NewRecorder(), req)
+
+	if calls != 2 {
+		t.Fatalf("expected the erroring handler to run on both requests, got %d calls", calls)
+	}
+}
+
+// TestWrap_BypassesCacheForNDJSON guards against a streamed response being
+// buffered into the cache (defeating the point of streaming) and then
+// replayed with the wrong Content-Type on a hit.
+func TestWrap_BypassesCacheForNDJSON(t *testing.T) {
+	c := newTestCache(t)
+	calls := 0
+	handler := c.Wrap(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_, _ = w.Write([]byte(`{"title":"a","label":"movie"}` + "\n"))
+	}, func(req *http.Request) string { return "key" })
+
+	req := httptest.NewRequest(http.MethodGet, "/graph", nil)
+	req.Header.Set("Accept", "application/x-ndjson")
+
+	for i := 0; i < 2; i++ {
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		if ct := rec.Header().Get("Content-Type"); ct != "application/x-ndjson" {
+			t.Fatalf("request %d: got Content-Type %q, want application/x-ndjson", i, ct)
+		}
+	}
+	if calls != 2 {
+		t.Fatalf("expected the NDJSON handler to run on both requests, got %d calls", calls)
+	}
+}
+
+// TestWrap_CachesSuccessfulResponse is the companion case: a handler that
+// does write a 200 body should be served out of the cache on the next call.
+func TestWrap_CachesSuccessfulResponse(t *testing.T) {
+	c := newTestCache(t)
+	calls := 0
+	handler := c.Wrap(func(w http.ResponseWriter, req *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}, func(req *http.Request) string { return "key" })
+
+	req := httptest.NewRequest(http.MethodGet, "/graph", nil)
+	handler(httptest.NewRecorder(), req)
+	handler(httptest.NewRecorder(), req)
+
+	if calls != 1 {
+		t.Fatalf("expected the second request to be served from cache, got %d calls", calls)
+	}
+}
@@ -0,0 +1,70 @@
+// Command worker runs the background job processor that executes the long
+// Cypher operations (bulk imports, co-star recomputes, graph precomputes)
+// enqueued by the HTTP server's /jobs endpoints.
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/neo4j-examples/movies-golang-bolt/cache"
+	"github.com/neo4j-examples/movies-golang-bolt/worker"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	database := lookupEnvOrGetDefault("NEO4J_DATABASE", "movies")
+	driver, err := neo4j.NewDriverWithContext(
+		lookupEnvOrGetDefault("NEO4J_URI", "neo4j+s://demo.neo4jlabs.com"),
+		neo4j.BasicAuth(
+			lookupEnvOrGetDefault("NEO4J_USER", "movies"),
+			lookupEnvOrGetDefault("NEO4J_PASSWORD", "movies"),
+			""))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer driver.Close(ctx)
+
+	queue, err := worker.NewQueue(lookupEnvOrGetDefault("JOB_QUEUE_PATH", "jobs.db"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	respCache := cache.New(cache.Configuration{
+		Host:     lookupEnvOrGetDefault("CACHE_HOST", "localhost"),
+		Port:     lookupEnvOrGetDefault("CACHE_PORT", "6379"),
+		Password: lookupEnvOrGetDefault("CACHE_PASSWORD", ""),
+		TTL:      cacheTTL(),
+	})
+
+	w := worker.New(queue, driver, database, map[worker.Type]worker.Handler{
+		worker.TypeImportMovies:    worker.ImportMovies,
+		worker.TypeRecomputeCoStar: worker.RecomputeCoStars,
+		worker.TypePrecomputeGraph: worker.PrecomputeGraphHandler(respCache),
+	})
+
+	log.Println("worker started, polling for jobs")
+	w.Run(ctx)
+	log.Println("worker stopped")
+}
+
+func lookupEnvOrGetDefault(key string, defaultValue string) string {
+	if env, found := os.LookupEnv(key); found {
+		return env
+	}
+	return defaultValue
+}
+
+func cacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(lookupEnvOrGetDefault("CACHE_TTL", "30s"))
+	if err != nil {
+		return 30 * time.Second
+	}
+	return ttl
+}
@@ -0,0 +1,118 @@
+// Package deadline derives cancellable, re-armable contexts for per-request
+// query timeouts. It plays the same role for an http.Request/context.Context
+// pair that net.Conn's SetReadDeadline/SetWriteDeadline play for a
+// connection: a single timer that can be moved, with a channel callers can
+// watch to know the underlying work was actually torn down.
+package deadline
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Clock abstracts time so tests can drive a Deadline without sleeping.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// Deadline wraps a context with a re-armable timeout. Canceling the parent
+// (e.g. the client disconnecting) and the timeout elapsing are distinguished
+// via Exceeded/Canceled, which callers use to pick between a 504 and a 499
+// response.
+type Deadline struct {
+	clock  Clock
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+
+	mu   sync.Mutex
+	gen  int
+	torn chan struct{}
+}
+
+// New derives a Deadline from parent that fires after timeout using the
+// real wall clock.
+func New(parent context.Context, timeout time.Duration) *Deadline {
+	return NewWithClock(parent, timeout, realClock{})
+}
+
+// NewWithClock is New with an injectable Clock, for tests.
+func NewWithClock(parent context.Context, timeout time.Duration, clock Clock) *Deadline {
+	ctx, cancel := context.WithCancelCause(parent)
+	d := &Deadline{clock: clock, ctx: ctx, cancel: cancel}
+	d.SetDeadline(clock.Now().Add(timeout))
+	return d
+}
+
+// Context returns the context that in-flight work should be run with.
+func (d *Deadline) Context() context.Context {
+	return d.ctx
+}
+
+// SetDeadline rearms the timer to fire at t, replacing any previous one.
+// Like net.Conn, a zero t disables the timeout entirely. Every call
+// invalidates the previous watch goroutine, and a call with a non-zero t
+// starts a fresh one, so an earlier timer firing late can never cancel past
+// what the latest call intended.
+func (d *Deadline) SetDeadline(t time.Time) {
+	d.mu.Lock()
+	d.gen++
+	gen := d.gen
+	torn := make(chan struct{})
+	d.torn = torn
+	d.mu.Unlock()
+
+	if t.IsZero() {
+		return
+	}
+	timer := d.clock.After(t.Sub(d.clock.Now()))
+	go d.watch(timer, gen, torn)
+}
+
+func (d *Deadline) watch(timer <-chan time.Time, gen int, torn chan struct{}) {
+	select {
+	case <-timer:
+		d.mu.Lock()
+		current := gen == d.gen
+		d.mu.Unlock()
+		if current {
+			d.cancel(context.DeadlineExceeded)
+		}
+	case <-d.ctx.Done():
+	}
+	close(torn)
+}
+
+// Torn reports whether the watch goroutine for the most recent SetDeadline
+// call has observed the context finish, i.e. that whatever was running
+// under Context() has actually been torn down rather than leaked.
+func (d *Deadline) Torn() <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.torn
+}
+
+// Exceeded reports whether the context ended because the deadline elapsed.
+func (d *Deadline) Exceeded() bool {
+	return context.Cause(d.ctx) == context.DeadlineExceeded
+}
+
+// Canceled reports whether the context ended because the parent (the
+// client's request context) was canceled, as opposed to the deadline. It
+// also reports true after Stop.
+func (d *Deadline) Canceled() bool {
+	return context.Cause(d.ctx) == context.Canceled
+}
+
+// Stop cancels the context without marking the deadline as exceeded, which
+// ends the watch goroutine and releases the Deadline's resources, for the
+// common case where the work finished before the deadline.
+func (d *Deadline) Stop() {
+	d.cancel(nil)
+}
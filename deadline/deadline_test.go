@@ -0,0 +1,85 @@
+package deadline
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock lets tests fire a timeout deterministically instead of sleeping.
+type fakeClock struct {
+	now chan time.Time
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: make(chan time.Time, 1)}
+}
+
+func (c *fakeClock) Now() time.Time { return time.Time{} }
+
+func (c *fakeClock) After(time.Duration) <-chan time.Time { return c.now }
+
+func (c *fakeClock) fire() { c.now <- time.Time{} }
+
+func TestDeadline_ExceededTearsDownContext(t *testing.T) {
+	clock := newFakeClock()
+	d := NewWithClock(context.Background(), time.Second, clock)
+
+	clock.fire()
+
+	select {
+	case <-d.Context().Done():
+	case <-time.After(time.Second):
+		t.Fatal("context was not canceled after the deadline fired")
+	}
+	select {
+	case <-d.Torn():
+	case <-time.After(time.Second):
+		t.Fatal("watch goroutine never reported teardown")
+	}
+
+	if !d.Exceeded() {
+		t.Error("expected Exceeded() to be true")
+	}
+	if d.Canceled() {
+		t.Error("expected Canceled() to be false for a timeout")
+	}
+}
+
+func TestDeadline_ParentCancelIsNotExceeded(t *testing.T) {
+	clock := newFakeClock()
+	parent, cancelParent := context.WithCancel(context.Background())
+	d := NewWithClock(parent, time.Hour, clock)
+
+	cancelParent()
+
+	select {
+	case <-d.Torn():
+	case <-time.After(time.Second):
+		t.Fatal("watch goroutine never reported teardown")
+	}
+
+	if d.Exceeded() {
+		t.Error("expected Exceeded() to be false for a client cancel")
+	}
+	if !d.Canceled() {
+		t.Error("expected Canceled() to be true")
+	}
+}
+
+func TestDeadline_StopDoesNotLeaveContextExceeded(t *testing.T) {
+	clock := newFakeClock()
+	d := NewWithClock(context.Background(), time.Hour, clock)
+
+	d.Stop()
+
+	select {
+	case <-d.Torn():
+	case <-time.After(time.Second):
+		t.Fatal("watch goroutine never reported teardown")
+	}
+
+	if d.Exceeded() {
+		t.Error("expected Exceeded() to be false after an explicit Stop")
+	}
+}
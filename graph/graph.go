@@ -0,0 +1,185 @@
+// Package graph builds the D3 force-graph payload /graph serves (and the
+// background worker precomputes), so both the HTTP handler and the
+// precompute job run the exact same query and dedup logic.
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Query is the Cypher both Build and StreamNDJSON run.
+const Query = `MATCH (m:Movie)<-[:ACTED_IN]-(a:Person)
+		  RETURN m.title AS movie, collect(a.name) AS cast
+		  LIMIT $limit `
+
+// Response is the D3 force-graph payload: Nodes indexed 0..n-1, Links
+// referencing those indexes, and an optional per-node cluster Group.
+type Response struct {
+	Nodes  []Node `json:"nodes"`
+	Links  []Link `json:"links"`
+	Groups []int  `json:"groups,omitempty"`
+}
+
+type Node struct {
+	Title string `json:"title"`
+	Label string `json:"label"`
+}
+
+type Link struct {
+	Source int `json:"source"`
+	Target int `json:"target"`
+}
+
+// CacheKeyPrefix is the common prefix of every cache key Build's response is
+// stored under, so callers can invalidate or precompute without duplicating
+// the format.
+const CacheKeyPrefix = "graph:"
+
+// CacheKey derives the cache key for the JSON payload Build returns for a
+// given limit, matching the key graphHandler's cache wrapper looks up.
+func CacheKey(limit int, clustered bool) string {
+	key := fmt.Sprintf("%s%d", CacheKeyPrefix, limit)
+	if clustered {
+		key += ":clustered"
+	}
+	return key
+}
+
+// Build runs Query and assembles the D3 node/link payload, deduplicating
+// actor nodes with a name->index map instead of an O(N*M) linear scan.
+func Build(ctx context.Context, driver neo4j.DriverWithContext, database string, limit int) (Response, error) {
+	result, err := neo4j.ExecuteQuery(ctx, driver, Query,
+		map[string]interface{}{"limit": limit},
+		neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithReadersRouting(),
+		neo4j.ExecuteQueryWithDatabase(database))
+	if err != nil {
+		return Response{}, err
+	}
+
+	var response Response
+	actorIndex := make(map[string]int)
+	for _, record := range result.Records {
+		title, _, _ := neo4j.GetRecordValue[string](record, "movie")
+		actors, _, _ := neo4j.GetRecordValue[[]any](record, "cast")
+		response.Nodes = append(response.Nodes, Node{Title: title, Label: "movie"})
+		movIdx := len(response.Nodes) - 1
+		for _, actor := range actors {
+			name := actor.(string)
+			idx, ok := actorIndex[name]
+			if !ok {
+				response.Nodes = append(response.Nodes, Node{Title: name, Label: "actor"})
+				idx = len(response.Nodes) - 1
+				actorIndex[name] = idx
+			}
+			response.Links = append(response.Links, Link{Source: idx, Target: movIdx})
+		}
+	}
+	return response, nil
+}
+
+// StreamNDJSON serves the same graph as Build, but writes one node or link
+// per line as records arrive from the driver instead of buffering the whole
+// EagerResult, so a large limit doesn't blow memory.
+func StreamNDJSON(ctx context.Context, w io.Writer, driver neo4j.DriverWithContext, database string, limit int) error {
+	session := driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: database,
+		AccessMode:   neo4j.AccessModeRead,
+	})
+	defer session.Close(ctx)
+
+	result, err := session.Run(ctx, Query, map[string]interface{}{"limit": limit})
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(w)
+	actorIndex := make(map[string]int)
+	nextIndex := 0
+	for result.Next(ctx) {
+		record := result.Record()
+		title, _, _ := neo4j.GetRecordValue[string](record, "movie")
+		actors, _, _ := neo4j.GetRecordValue[[]any](record, "cast")
+
+		if err := encoder.Encode(Node{Title: title, Label: "movie"}); err != nil {
+			return err
+		}
+		movIdx := nextIndex
+		nextIndex++
+
+		for _, actor := range actors {
+			name := actor.(string)
+			idx, ok := actorIndex[name]
+			if !ok {
+				if err := encoder.Encode(Node{Title: name, Label: "actor"}); err != nil {
+					return err
+				}
+				idx = nextIndex
+				nextIndex++
+				actorIndex[name] = idx
+			}
+			if err := encoder.Encode(Link{Source: idx, Target: movIdx}); err != nil {
+				return err
+			}
+		}
+	}
+	return result.Err()
+}
+
+// unionFind is a standard disjoint-set structure used to cluster graph
+// nodes that co-appear into connected components.
+type unionFind struct {
+	parent []int
+}
+
+func newUnionFind(n int) *unionFind {
+	parent := make([]int, n)
+	for i := range parent {
+		parent[i] = i
+	}
+	return &unionFind{parent: parent}
+}
+
+func (u *unionFind) find(i int) int {
+	for u.parent[i] != i {
+		u.parent[i] = u.parent[u.parent[i]]
+		i = u.parent[i]
+	}
+	return i
+}
+
+func (u *unionFind) union(a, b int) {
+	rootA, rootB := u.find(a), u.find(b)
+	if rootA != rootB {
+		u.parent[rootB] = rootA
+	}
+}
+
+// ClusterGroups assigns each node the id of the connected component it
+// belongs to (by co-appearance, i.e. linked directly or transitively),
+// compacted to 0..k-1 so groups[i] is cheap for a client to use as a color
+// index.
+func ClusterGroups(nodes []Node, links []Link) []int {
+	uf := newUnionFind(len(nodes))
+	for _, link := range links {
+		uf.union(link.Source, link.Target)
+	}
+
+	groups := make([]int, len(nodes))
+	componentID := make(map[int]int)
+	for i := range nodes {
+		root := uf.find(i)
+		id, ok := componentID[root]
+		if !ok {
+			id = len(componentID)
+			componentID[root] = id
+		}
+		groups[i] = id
+	}
+	return groups
+}
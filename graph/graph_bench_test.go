@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"fmt"
+	"testing"
+)
+
+type graphRow struct {
+	movie string
+	cast  []string
+}
+
+// syntheticGraphRows builds a movie/cast graph shaped like the real one:
+// each actor recurs across several movies, which is what makes actor
+// dedup the dominant cost at scale.
+func syntheticGraphRows(movies int) []graphRow {
+	rows := make([]graphRow, movies)
+	for i := 0; i < movies; i++ {
+		rows[i] = graphRow{
+			movie: fmt.Sprintf("movie-%d", i),
+			cast: []string{
+				fmt.Sprintf("actor-%d", i%50),
+				fmt.Sprintf("actor-%d", (i+1)%50),
+				fmt.Sprintf("actor-%d", (i+2)%50),
+			},
+		}
+	}
+	return rows
+}
+
+// buildGraphLinearScan is the dedup strategy graphHandler used before the
+// map-based rewrite: an O(N*M) nested scan of already-seen nodes per actor.
+func buildGraphLinearScan(rows []graphRow) Response {
+	var response Response
+	for _, row := range rows {
+		response.Nodes = append(response.Nodes, Node{Title: row.movie, Label: "movie"})
+		movIdx := len(response.Nodes) - 1
+		for _, actor := range row.cast {
+			idx := -1
+			for i, node := range response.Nodes {
+				if actor == node.Title && node.Label == "actor" {
+					idx = i
+					break
+				}
+			}
+			if idx == -1 {
+				response.Nodes = append(response.Nodes, Node{Title: actor, Label: "actor"})
+				response.Links = append(response.Links, Link{Source: len(response.Nodes) - 1, Target: movIdx})
+			} else {
+				response.Links = append(response.Links, Link{Source: idx, Target: movIdx})
+			}
+		}
+	}
+	return response
+}
+
+// buildGraphMapDedup is the map[string]int lookup Build uses.
+func buildGraphMapDedup(rows []graphRow) Response {
+	var response Response
+	actorIndex := make(map[string]int)
+	for _, row := range rows {
+		response.Nodes = append(response.Nodes, Node{Title: row.movie, Label: "movie"})
+		movIdx := len(response.Nodes) - 1
+		for _, actor := range row.cast {
+			idx, ok := actorIndex[actor]
+			if !ok {
+				response.Nodes = append(response.Nodes, Node{Title: actor, Label: "actor"})
+				idx = len(response.Nodes) - 1
+				actorIndex[actor] = idx
+			}
+			response.Links = append(response.Links, Link{Source: idx, Target: movIdx})
+		}
+	}
+	return response
+}
+
+func BenchmarkBuildGraph_LinearScan_Limit5000(b *testing.B) {
+	rows := syntheticGraphRows(5000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buildGraphLinearScan(rows)
+	}
+}
+
+func BenchmarkBuildGraph_MapDedup_Limit5000(b *testing.B) {
+	rows := syntheticGraphRows(5000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buildGraphMapDedup(rows)
+	}
+}
@@ -0,0 +1,147 @@
+// Package search executes movie title/tagline searches against Neo4j,
+// preferring a full-text index and falling back to a CONTAINS scan on
+// deployments (typically Community edition) where full-text indexes
+// aren't available.
+package search
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// Mode selects how a Searcher executes a query.
+type Mode int
+
+const (
+	// ModeFulltext uses db.index.fulltext.queryNodes against the
+	// movieTitles index and supports full Lucene query syntax.
+	ModeFulltext Mode = iota
+	// ModeContains falls back to toLower(...) CONTAINS toLower(...).
+	ModeContains
+)
+
+// Result is a single scored match. Score is always 1.0 under ModeContains,
+// which has no notion of relevance ranking.
+type Result struct {
+	Title    string  `json:"title"`
+	Tagline  string  `json:"tagline,omitempty"`
+	Votes    int64   `json:"votes,omitempty"`
+	Released int64   `json:"released,omitempty"`
+	Score    float64 `json:"score"`
+}
+
+// Searcher executes title/tagline searches using whichever Mode the
+// connected deployment supports.
+type Searcher struct {
+	driver   neo4j.DriverWithContext
+	database string
+	mode     Mode
+}
+
+// New detects the deployment's full-text support via dbms.components() and
+// returns a Searcher configured for it. Call EnsureIndex once at startup
+// before serving requests.
+func New(ctx context.Context, driver neo4j.DriverWithContext, database string) (*Searcher, error) {
+	mode, err := detectMode(ctx, driver, database)
+	if err != nil {
+		return nil, err
+	}
+	return &Searcher{driver: driver, database: database, mode: mode}, nil
+}
+
+func detectMode(ctx context.Context, driver neo4j.DriverWithContext, database string) (Mode, error) {
+	result, err := neo4j.ExecuteQuery(ctx, driver, `CALL dbms.components() YIELD edition RETURN edition`,
+		nil,
+		neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(database))
+	if err != nil {
+		return ModeContains, err
+	}
+	for _, record := range result.Records {
+		edition, _, _ := neo4j.GetRecordValue[string](record, "edition")
+		if edition == "community" {
+			return ModeContains, nil
+		}
+	}
+	return ModeFulltext, nil
+}
+
+// EnsureIndex creates the movieTitles full-text index if this deployment
+// supports it. It is a no-op under ModeContains.
+func (s *Searcher) EnsureIndex(ctx context.Context) error {
+	if s.mode != ModeFulltext {
+		return nil
+	}
+	_, err := neo4j.ExecuteQuery(ctx, s.driver,
+		`CREATE FULLTEXT INDEX movieTitles IF NOT EXISTS FOR (m:Movie) ON EACH [m.title, m.tagline]`,
+		nil,
+		neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(s.database))
+	return err
+}
+
+// Search runs query and returns up to limit matches starting at offset.
+// Under ModeFulltext, query is Lucene syntax: "exact phrase", title~2
+// fuzzy, +required -excluded.
+func (s *Searcher) Search(ctx context.Context, query string, limit, offset int) ([]Result, error) {
+	if s.mode == ModeFulltext {
+		return s.searchFulltext(ctx, query, limit, offset)
+	}
+	return s.searchContains(ctx, query, limit, offset)
+}
+
+func (s *Searcher) searchFulltext(ctx context.Context, query string, limit, offset int) ([]Result, error) {
+	result, err := neo4j.ExecuteQuery(ctx, s.driver, `CALL db.index.fulltext.queryNodes('movieTitles', $query) YIELD node, score
+			RETURN node.title AS title, node.tagline AS tagline, node.votes AS votes, node.released AS released, score
+			SKIP $offset LIMIT $limit`,
+		map[string]interface{}{"query": query, "offset": offset, "limit": limit},
+		neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithReadersRouting(),
+		neo4j.ExecuteQueryWithDatabase(s.database))
+	if err != nil {
+		return nil, err
+	}
+	return recordsToResults(result.Records), nil
+}
+
+func (s *Searcher) searchContains(ctx context.Context, query string, limit, offset int) ([]Result, error) {
+	result, err := neo4j.ExecuteQuery(ctx, s.driver, `MATCH (movie:Movie)
+			WHERE toLower(movie.title) CONTAINS toLower($title)
+			RETURN movie.title AS title, movie.tagline AS tagline, movie.votes AS votes, movie.released AS released, 1.0 AS score
+			SKIP $offset LIMIT $limit`,
+		map[string]interface{}{"title": stripLuceneOperators(query), "offset": offset, "limit": limit},
+		neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithReadersRouting(),
+		neo4j.ExecuteQueryWithDatabase(s.database))
+	if err != nil {
+		return nil, err
+	}
+	return recordsToResults(result.Records), nil
+}
+
+func recordsToResults(records []*neo4j.Record) []Result {
+	results := make([]Result, len(records))
+	for i, record := range records {
+		title, _, _ := neo4j.GetRecordValue[string](record, "title")
+		tagline, _, _ := neo4j.GetRecordValue[string](record, "tagline")
+		votes, _, _ := neo4j.GetRecordValue[int64](record, "votes")
+		released, _, _ := neo4j.GetRecordValue[int64](record, "released")
+		score, _, _ := neo4j.GetRecordValue[float64](record, "score")
+		results[i] = Result{Title: title, Tagline: tagline, Votes: votes, Released: released, Score: score}
+	}
+	return results
+}
+
+var fuzzyOperator = regexp.MustCompile(`~\d*`)
+
+// stripLuceneOperators degrades a Lucene-style query to a plain substring
+// for ModeContains, which has no concept of phrases, fuzziness, or
+// required/excluded terms.
+func stripLuceneOperators(query string) string {
+	query = fuzzyOperator.ReplaceAllString(query, "")
+	query = strings.NewReplacer(`"`, "", "+", "", "-", " ").Replace(query)
+	return strings.TrimSpace(query)
+}
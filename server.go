@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
@@ -12,14 +13,17 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
-
+	"time"
+
+	"github.com/neo4j-examples/movies-golang-bolt/auth"
+	"github.com/neo4j-examples/movies-golang-bolt/cache"
+	"github.com/neo4j-examples/movies-golang-bolt/deadline"
+	"github.com/neo4j-examples/movies-golang-bolt/graph"
+	"github.com/neo4j-examples/movies-golang-bolt/search"
+	"github.com/neo4j-examples/movies-golang-bolt/worker"
 	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
 )
 
-type MovieResult struct {
-	Movie `json:"movie"`
-}
-
 type VoteResult struct {
 	Updates int `json:"updates"`
 }
@@ -38,21 +42,6 @@ type Person struct {
 	Name string   `json:"name"`
 }
 
-type D3Response struct {
-	Nodes []Node `json:"nodes"`
-	Links []Link `json:"links"`
-}
-
-type Node struct {
-	Title string `json:"title"`
-	Label string `json:"label"`
-}
-
-type Link struct {
-	Source int `json:"source"`
-	Target int `json:"target"`
-}
-
 type Neo4jConfiguration struct {
 	Url      string
 	Username string
@@ -78,43 +67,37 @@ func defaultHandler(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func searchHandlerFunc(ctx context.Context, driver neo4j.DriverWithContext, database string) func(http.ResponseWriter, *http.Request) {
+func searchHandlerFunc(searcher *search.Searcher) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, req *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		result, err := neo4j.ExecuteQuery(ctx, driver, `MATCH (movie:Movie)
-				 WHERE toLower(movie.title) CONTAINS toLower($title)
-				 RETURN movie.title AS title, movie.tagline AS tagline, movie.votes AS votes, movie.released AS released`,
-			map[string]interface{}{"title": req.URL.Query().Get("q")},
-			neo4j.EagerResultTransformer,
-			neo4j.ExecuteQueryWithReadersRouting(),
-			neo4j.ExecuteQueryWithDatabase(database))
+		dl := deadline.New(req.Context(), queryTimeout())
+		defer dl.Stop()
+
+		results, err := searcher.Search(dl.Context(), req.URL.Query().Get("q"), parseLimit(req), parseOffset(req))
 		if err != nil {
-			log.Println("error querying search:", err)
+			if !writeQueryTimeoutStatus(w, dl) {
+				log.Println("error querying search:", err)
+			}
 			return
 		}
 
-		movies := make([]MovieResult, len(result.Records))
-		for i, record := range result.Records {
-			released, _, _ := neo4j.GetRecordValue[int64](record, "released")
-			title, _, _ := neo4j.GetRecordValue[string](record, "title")
-			tagline, _, _ := neo4j.GetRecordValue[string](record, "tagline")
-			votes, _, _ := neo4j.GetRecordValue[int64](record, "votes")
-			movies[i] = MovieResult{Movie{Released: released, Title: title, Tagline: tagline, Votes: votes}}
-		}
-		err = json.NewEncoder(w).Encode(movies)
+		err = json.NewEncoder(w).Encode(results)
 		if err != nil {
 			log.Println("error writing search response:", err)
 		}
 	}
 }
 
-func movieHandlerFunc(ctx context.Context, driver neo4j.DriverWithContext, database string) func(http.ResponseWriter, *http.Request) {
+func movieHandlerFunc(driver neo4j.DriverWithContext, database string) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, req *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
+		dl := deadline.New(req.Context(), queryTimeout())
+		defer dl.Stop()
+
 		title, _ := url.QueryUnescape(req.URL.Path[len("/movie/"):])
-		result, err := neo4j.ExecuteQuery(ctx, driver, `MATCH (movie:Movie {title:$title})
+		result, err := neo4j.ExecuteQuery(dl.Context(), driver, `MATCH (movie:Movie {title:$title})
 				OPTIONAL MATCH (movie)<-[r]-(person:Person)
 				WITH movie.title AS title,
 					 collect({
@@ -145,7 +128,9 @@ func movieHandlerFunc(ctx context.Context, driver neo4j.DriverWithContext, datab
 			}
 		}
 		if err != nil {
-			log.Println("error querying movie:", err)
+			if !writeQueryTimeoutStatus(w, dl) {
+				log.Println("error querying movie:", err)
+			}
 			return
 		}
 		err = json.NewEncoder(w).Encode(movie)
@@ -155,14 +140,107 @@ func movieHandlerFunc(ctx context.Context, driver neo4j.DriverWithContext, datab
 	}
 }
 
-func voteInMovieHandlerFunc(ctx context.Context, driver neo4j.DriverWithContext, database string) func(http.ResponseWriter, *http.Request) {
+func registerHandlerFunc(driver neo4j.DriverWithContext, database string, constraintEnsured bool) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, req *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
+		var body struct{ Email, Password string }
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		user, err := auth.Register(req.Context(), driver, database, body.Email, body.Password, constraintEnsured)
+		if err == auth.ErrEmailTaken {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		if err != nil {
+			log.Println("error registering user:", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(user); err != nil {
+			log.Println("error writing register response:", err)
+		}
+	}
+}
+
+func loginHandlerFunc(driver neo4j.DriverWithContext, database string, jwtSecret []byte) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var body struct{ Email, Password string }
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		user, err := auth.Login(req.Context(), driver, database, body.Email, body.Password)
+		if err == auth.ErrInvalidCredentials {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			log.Println("error logging in user:", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		token, err := auth.NewToken(jwtSecret, user.ID)
+		if err != nil {
+			log.Println("error signing token:", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(struct {
+			Token string `json:"token"`
+		}{token}); err != nil {
+			log.Println("error writing login response:", err)
+		}
+	}
+}
+
+func meVotesHandlerFunc(driver neo4j.DriverWithContext, database string) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		result, err := neo4j.ExecuteQuery(req.Context(), driver, `MATCH (:User {id: $uid})-[:VOTED]->(m:Movie)
+				RETURN m.title AS title
+				ORDER BY m.title`,
+			map[string]interface{}{"uid": auth.UserID(req.Context())},
+			neo4j.EagerResultTransformer,
+			neo4j.ExecuteQueryWithReadersRouting(),
+			neo4j.ExecuteQueryWithDatabase(database))
+		if err != nil {
+			log.Println("error listing voted movies:", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		titles := make([]string, len(result.Records))
+		for i, record := range result.Records {
+			titles[i], _, _ = neo4j.GetRecordValue[string](record, "title")
+		}
+		if err := json.NewEncoder(w).Encode(titles); err != nil {
+			log.Println("error writing votes response:", err)
+		}
+	}
+}
+
+func voteInMovieHandlerFunc(driver neo4j.DriverWithContext, database string, respCache *cache.Cache) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		dl := deadline.New(req.Context(), queryTimeout())
+		defer dl.Stop()
+
 		title, _ := url.QueryUnescape(req.URL.Path[len("/movie/vote/"):])
-		result, err := neo4j.ExecuteQuery(ctx, driver, `MATCH (m:Movie {title: $title})
-				SET m.votes = coalesce(m.votes, 0) + 1`,
-			map[string]interface{}{"title": title},
+		result, err := neo4j.ExecuteQuery(dl.Context(), driver, `MATCH (m:Movie {title: $title})
+				MERGE (u:User {id: $uid})
+				MERGE (u)-[v:VOTED]->(m)
+				ON CREATE SET m.votes = coalesce(m.votes, 0) + 1`,
+			map[string]interface{}{"title": title, "uid": auth.UserID(req.Context())},
 			neo4j.EagerResultTransformer,
 			neo4j.ExecuteQueryWithDatabase(database))
 
@@ -170,9 +248,22 @@ func voteInMovieHandlerFunc(ctx context.Context, driver neo4j.DriverWithContext,
 		vote.Updates = result.Summary.Counters().PropertiesSet()
 
 		if err != nil {
-			log.Println("error voting for movie:", err)
+			if !writeQueryTimeoutStatus(w, dl) {
+				log.Println("error voting for movie:", err)
+			}
 			return
 		}
+		if respCache != nil {
+			if err := respCache.Del(req.Context(), movieCacheKey(title)); err != nil {
+				log.Println("error invalidating movie cache after vote:", err)
+			}
+			if err := respCache.DelPrefix(req.Context(), graph.CacheKeyPrefix); err != nil {
+				log.Println("error invalidating graph cache after vote:", err)
+			}
+			if err := respCache.DelPrefix(req.Context(), searchCacheKeyPrefix); err != nil {
+				log.Println("error invalidating search cache after vote:", err)
+			}
+		}
 		err = json.NewEncoder(w).Encode(vote)
 		if err != nil {
 			log.Println("error writing vote result response:", err)
@@ -180,51 +271,107 @@ func voteInMovieHandlerFunc(ctx context.Context, driver neo4j.DriverWithContext,
 	}
 }
 
-func graphHandler(ctx context.Context, driver neo4j.DriverWithContext, database string) func(http.ResponseWriter, *http.Request) {
+func graphHandler(driver neo4j.DriverWithContext, database string) func(http.ResponseWriter, *http.Request) {
 	return func(w http.ResponseWriter, req *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
+		dl := deadline.New(req.Context(), queryTimeout())
+		defer dl.Stop()
 
-		result, err := neo4j.ExecuteQuery(ctx, driver, `MATCH (m:Movie)<-[:ACTED_IN]-(a:Person)
-				  RETURN m.title AS movie, collect(a.name) AS cast
-				  LIMIT $limit `,
-			map[string]interface{}{"limit": parseLimit(req)},
-			neo4j.EagerResultTransformer,
-			neo4j.ExecuteQueryWithReadersRouting(),
-			neo4j.ExecuteQueryWithDatabase(database))
+		limit := parseLimit(req)
+		cluster := req.URL.Query().Get("cluster") == "true"
 
-		var d3Response D3Response
-		for _, record := range result.Records {
-			title, _, _ := neo4j.GetRecordValue[string](record, "movie")
-			actors, _, _ := neo4j.GetRecordValue[[]any](record, "cast")
-			d3Response.Nodes = append(d3Response.Nodes, Node{Title: title, Label: "movie"})
-			movIdx := len(d3Response.Nodes) - 1
-			for _, actor := range actors {
-				idx := -1
-				for i, node := range d3Response.Nodes {
-					if actor == node.Title && node.Label == "actor" {
-						idx = i
-						break
-					}
-				}
-				if idx == -1 {
-					d3Response.Nodes = append(d3Response.Nodes, Node{Title: actor.(string), Label: "actor"})
-					d3Response.Links = append(d3Response.Links, Link{Source: len(d3Response.Nodes) - 1, Target: movIdx})
-				} else {
-					d3Response.Links = append(d3Response.Links, Link{Source: idx, Target: movIdx})
+		if acceptsNDJSON(req) {
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			if err := graph.StreamNDJSON(dl.Context(), w, driver, database, limit); err != nil {
+				if !writeQueryTimeoutStatus(w, dl) {
+					log.Println("error streaming graph:", err)
 				}
 			}
+			return
 		}
+
+		w.Header().Set("Content-Type", "application/json")
+		response, err := graph.Build(dl.Context(), driver, database, limit)
 		if err != nil {
-			log.Println("error querying graph:", err)
+			if !writeQueryTimeoutStatus(w, dl) {
+				log.Println("error querying graph:", err)
+			}
 			return
 		}
-		err = json.NewEncoder(w).Encode(d3Response)
-		if err != nil {
+		if cluster {
+			response.Groups = graph.ClusterGroups(response.Nodes, response.Links)
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
 			log.Println("error writing graph response:", err)
 		}
 	}
 }
 
+func acceptsNDJSON(req *http.Request) bool {
+	return strings.Contains(req.Header.Get("Accept"), "application/x-ndjson")
+}
+
+func jobsHandlerFunc(queue *worker.Queue) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		switch req.Method {
+		case http.MethodPost:
+			var body struct {
+				ClientKey string          `json:"clientKey"`
+				Type      worker.Type     `json:"type"`
+				Params    json.RawMessage `json:"params"`
+			}
+			if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			job, err := queue.Enqueue(req.Context(), body.ClientKey, body.Type, body.Params)
+			if err != nil {
+				log.Println("error enqueuing job:", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusAccepted)
+			if err := json.NewEncoder(w).Encode(job); err != nil {
+				log.Println("error writing job response:", err)
+			}
+		case http.MethodGet:
+			jobs, err := queue.List(req.Context())
+			if err != nil {
+				log.Println("error listing jobs:", err)
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if err := json.NewEncoder(w).Encode(jobs); err != nil {
+				log.Println("error writing jobs response:", err)
+			}
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func jobHandlerFunc(queue *worker.Queue) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		id := strings.TrimPrefix(req.URL.Path, "/jobs/")
+		job, err := queue.Get(req.Context(), id)
+		if err == sql.ErrNoRows {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			log.Println("error fetching job:", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(job); err != nil {
+			log.Println("error writing job response:", err)
+		}
+	}
+}
+
 func toStringSlice(slice []interface{}) []string {
 	var result []string
 	for _, e := range slice {
@@ -241,12 +388,62 @@ func main() {
 		log.Fatal(err)
 	}
 	defer unsafeClose(ctx, driver)
+
+	jobQueue, err := worker.NewQueue(lookupEnvOrGetDefault("JOB_QUEUE_PATH", "jobs.db"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	respCache := cache.New(cache.Configuration{
+		Host:     lookupEnvOrGetDefault("CACHE_HOST", "localhost"),
+		Port:     lookupEnvOrGetDefault("CACHE_PORT", "6379"),
+		Password: lookupEnvOrGetDefault("CACHE_PASSWORD", ""),
+		TTL:      cacheTTL(),
+	})
+
+	// Unlike the demo Neo4j credentials (which only grant access to a public
+	// read-only sandbox), this secret *is* the auth boundary: anyone who
+	// reads it can mint a valid token for any userID. There's no safe
+	// default, so fail startup instead of silently falling back to one.
+	jwtSecretEnv, ok := os.LookupEnv("JWT_SECRET")
+	if !ok || jwtSecretEnv == "" {
+		log.Fatal("JWT_SECRET must be set")
+	}
+	jwtSecret := []byte(jwtSecretEnv)
+
+	// Best-effort, same reasoning as the full-text index below: a read-only
+	// demo database can't create constraints. Register falls back to a
+	// check-then-create query when constraintEnsured is false.
+	constraintEnsured := true
+	if err := auth.EnsureConstraints(ctx, driver, configuration.Database); err != nil {
+		log.Println("warning: could not ensure user_email_unique constraint:", err)
+		constraintEnsured = false
+	}
+
+	searcher, err := search.New(ctx, driver, configuration.Database)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Best-effort: a read-only connection (e.g. the public demo database)
+	// can't create indexes, but search still works via the CONTAINS
+	// fallback, so a failure here shouldn't stop the server from starting.
+	if err := searcher.EnsureIndex(ctx); err != nil {
+		log.Println("warning: could not ensure movieTitles full-text index:", err)
+	}
+
 	serveMux := http.NewServeMux()
 	serveMux.HandleFunc("/", defaultHandler)
-	serveMux.HandleFunc("/search", searchHandlerFunc(ctx, driver, configuration.Database))
-	serveMux.HandleFunc("/movie/vote/", voteInMovieHandlerFunc(ctx, driver, configuration.Database))
-	serveMux.HandleFunc("/movie/", movieHandlerFunc(ctx, driver, configuration.Database))
-	serveMux.HandleFunc("/graph", graphHandler(ctx, driver, configuration.Database))
+	serveMux.HandleFunc("/search", respCache.Wrap(searchHandlerFunc(searcher), searchCacheKey))
+	serveMux.HandleFunc("/auth/register", registerHandlerFunc(driver, configuration.Database, constraintEnsured))
+	serveMux.HandleFunc("/auth/login", loginHandlerFunc(driver, configuration.Database, jwtSecret))
+	serveMux.HandleFunc("/me/votes", auth.RequireAuth(jwtSecret, meVotesHandlerFunc(driver, configuration.Database)))
+	serveMux.HandleFunc("/movie/vote/", auth.RequireAuth(jwtSecret, voteInMovieHandlerFunc(driver, configuration.Database, respCache)))
+	serveMux.HandleFunc("/movie/", respCache.Wrap(movieHandlerFunc(driver, configuration.Database), movieCacheKeyFromRequest))
+	serveMux.HandleFunc("/graph", respCache.Wrap(graphHandler(driver, configuration.Database), graphCacheKey))
+	serveMux.HandleFunc("/cache/flush", cacheFlushHandlerFunc(respCache, os.Getenv("CACHE_ADMIN_SECRET")))
+	jobsAdminSecret := os.Getenv("JOBS_ADMIN_SECRET")
+	serveMux.HandleFunc("/jobs", auth.RequireAuth(jwtSecret, requireAdminSecret(jobsAdminSecret, jobsHandlerFunc(jobQueue))))
+	serveMux.HandleFunc("/jobs/", auth.RequireAuth(jwtSecret, requireAdminSecret(jobsAdminSecret, jobHandlerFunc(jobQueue))))
 
 	var port string
 	var found bool
@@ -257,6 +454,88 @@ func main() {
 	panic(http.ListenAndServe(":"+port, serveMux))
 }
 
+// queryTimeout returns the per-request Cypher timeout from
+// NEO4J_QUERY_TIMEOUT (a Go duration string such as "5s"), defaulting to 5
+// seconds when unset or malformed.
+func queryTimeout() time.Duration {
+	d, err := time.ParseDuration(lookupEnvOrGetDefault("NEO4J_QUERY_TIMEOUT", "5s"))
+	if err != nil {
+		return 5 * time.Second
+	}
+	return d
+}
+
+// writeQueryTimeoutStatus, given a query error, writes the response status
+// a client should see for it: 504 when our own deadline fired first, 499
+// (the de facto "client closed request" status) when the caller disconnected
+// before we did. It reports whether it wrote a status, so callers only fall
+// back to logging an unexpected error.
+func writeQueryTimeoutStatus(w http.ResponseWriter, dl *deadline.Deadline) bool {
+	switch {
+	case dl.Exceeded():
+		w.WriteHeader(http.StatusGatewayTimeout)
+		return true
+	case dl.Canceled():
+		w.WriteHeader(499)
+		return true
+	default:
+		return false
+	}
+}
+
+const searchCacheKeyPrefix = "search:"
+
+// searchCacheKey, movieCacheKey and graphCacheKey derive cache.KeyFunc keys
+// from the same parameters each handler already reads off the request, so a
+// cache hit only ever serves a response identical to what the handler would
+// have produced.
+func searchCacheKey(req *http.Request) string {
+	return fmt.Sprintf("%s%s:%d:%d", searchCacheKeyPrefix, strings.ToLower(req.URL.Query().Get("q")), parseLimit(req), parseOffset(req))
+}
+
+func movieCacheKey(title string) string {
+	return "movie:" + strings.ToLower(title)
+}
+
+func movieCacheKeyFromRequest(req *http.Request) string {
+	title, _ := url.QueryUnescape(req.URL.Path[len("/movie/"):])
+	return movieCacheKey(title)
+}
+
+// graphCacheKey is only consulted for JSON requests: Wrap bypasses the cache
+// entirely for NDJSON (see cache/middleware.go), so that shape never needs a
+// key here.
+func graphCacheKey(req *http.Request) string {
+	return graph.CacheKey(parseLimit(req), req.URL.Query().Get("cluster") == "true")
+}
+
+func cacheFlushHandlerFunc(respCache *cache.Cache, adminSecret string) func(http.ResponseWriter, *http.Request) {
+	return requireAdminSecret(adminSecret, func(w http.ResponseWriter, req *http.Request) {
+		if err := respCache.Flush(req.Context()); err != nil {
+			log.Println("error flushing cache:", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// requireAdminSecret gates next behind a constant X-Admin-Secret header
+// rather than anything in the user/JWT model: it's meant for operator-only
+// routes like /cache/flush and /jobs, where "logged in" (any self-registered
+// user) isn't the same thing as "trusted to do this". An empty adminSecret
+// always 401s instead of leaving the route open, the same failsafe the JWT
+// secret check uses.
+func requireAdminSecret(adminSecret string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if adminSecret == "" || req.Header.Get("X-Admin-Secret") != adminSecret {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		next(w, req)
+	}
+}
+
 func parseLimit(req *http.Request) int {
 	limits := req.URL.Query()["limit"]
 	limit := 50
@@ -269,6 +548,27 @@ func parseLimit(req *http.Request) int {
 	return limit
 }
 
+func parseOffset(req *http.Request) int {
+	offsets := req.URL.Query()["offset"]
+	offset := 0
+	if len(offsets) > 0 {
+		if parsed, err := strconv.Atoi(offsets[0]); err == nil {
+			offset = parsed
+		}
+	}
+	return offset
+}
+
+// cacheTTL reads CACHE_TTL as a Go duration string (e.g. "30s"), defaulting
+// to 30 seconds when unset or malformed.
+func cacheTTL() time.Duration {
+	ttl, err := time.ParseDuration(lookupEnvOrGetDefault("CACHE_TTL", "30s"))
+	if err != nil {
+		return 30 * time.Second
+	}
+	return ttl
+}
+
 func parseConfiguration() *Neo4jConfiguration {
 	database := lookupEnvOrGetDefault("NEO4J_DATABASE", "movies")
 	if !strings.HasPrefix(lookupEnvOrGetDefault("NEO4J_VERSION", "4"), "4") {
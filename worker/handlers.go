@@ -0,0 +1,105 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/neo4j-examples/movies-golang-bolt/cache"
+	"github.com/neo4j-examples/movies-golang-bolt/graph"
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// importMoviesParams is the expected shape of Job.Params for TypeImportMovies.
+type importMoviesParams struct {
+	URL    string `json:"url"`
+	Format string `json:"format"` // "json" or "csv"
+}
+
+// ImportMovies bulk-loads movies from a JSON or CSV document at Params.URL
+// using UNWIND so the whole file is sent to Neo4j in a single transaction.
+func ImportMovies(ctx context.Context, driver neo4j.DriverWithContext, database string, job *Job) (*Summary, string, error) {
+	var params importMoviesParams
+	if err := json.Unmarshal(job.Params, &params); err != nil {
+		return nil, "", fmt.Errorf("invalid import_movies params: %w", err)
+	}
+
+	rows, err := fetchRows(ctx, params.URL, params.Format)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result, err := neo4j.ExecuteQuery(ctx, driver, `UNWIND $rows AS row
+			MERGE (m:Movie {title: row.title})
+			SET m.tagline = row.tagline, m.released = row.released`,
+		map[string]interface{}{"rows": rows},
+		neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(database))
+	if err != nil {
+		return nil, "", fmt.Errorf("importing movies: %w", err)
+	}
+
+	counters := result.Summary.Counters()
+	return &Summary{
+		NodesCreated:         counters.NodesCreated(),
+		RelationshipsCreated: counters.RelationshipsCreated(),
+	}, "", nil
+}
+
+// RecomputeCoStars merges a :CO_STARRED relationship between every pair of
+// actors who acted in the same movie, so the projection stays in sync as new
+// ACTED_IN relationships are added.
+func RecomputeCoStars(ctx context.Context, driver neo4j.DriverWithContext, database string, job *Job) (*Summary, string, error) {
+	result, err := neo4j.ExecuteQuery(ctx, driver, `MATCH (a:Person)-[:ACTED_IN]->(:Movie)<-[:ACTED_IN]-(b:Person)
+			WHERE id(a) < id(b)
+			MERGE (a)-[:CO_STARRED]-(b)`,
+		nil,
+		neo4j.EagerResultTransformer,
+		neo4j.ExecuteQueryWithDatabase(database))
+	if err != nil {
+		return nil, "", fmt.Errorf("recomputing co-stars: %w", err)
+	}
+
+	counters := result.Summary.Counters()
+	return &Summary{
+		NodesCreated:         counters.NodesCreated(),
+		RelationshipsCreated: counters.RelationshipsCreated(),
+	}, "", nil
+}
+
+// precomputeGraphParams is the expected shape of Job.Params for TypePrecomputeGraph.
+type precomputeGraphParams struct {
+	Limit int `json:"limit"`
+}
+
+// PrecomputeGraphHandler returns a Handler that runs the same query
+// graphHandler serves on demand and stores the result in respCache under
+// the key graphHandler's cache wrapper looks up, so a scheduled refresh
+// actually warms the cache for a popular limit instead of just recomputing
+// and discarding the result.
+func PrecomputeGraphHandler(respCache *cache.Cache) Handler {
+	return func(ctx context.Context, driver neo4j.DriverWithContext, database string, job *Job) (*Summary, string, error) {
+		var params precomputeGraphParams
+		if err := json.Unmarshal(job.Params, &params); err != nil {
+			return nil, "", fmt.Errorf("invalid precompute_graph params: %w", err)
+		}
+		if params.Limit <= 0 {
+			params.Limit = 50
+		}
+
+		response, err := graph.Build(ctx, driver, database, params.Limit)
+		if err != nil {
+			return nil, "", fmt.Errorf("precomputing graph: %w", err)
+		}
+
+		body, err := json.Marshal(response)
+		if err != nil {
+			return nil, "", fmt.Errorf("marshaling precomputed graph: %w", err)
+		}
+		if err := respCache.Set(ctx, graph.CacheKey(params.Limit, false), body); err != nil {
+			return nil, "", fmt.Errorf("caching precomputed graph: %w", err)
+		}
+
+		return &Summary{}, fmt.Sprintf("/graph?limit=%d", params.Limit), nil
+	}
+}
@@ -0,0 +1,168 @@
+package worker
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// maxImportBytes caps how much of a remote dataset fetchRows will read, so a
+// large or hostile response can't exhaust memory.
+const maxImportBytes = 50 << 20 // 50MB
+
+// maxImportRows caps how many rows a single import_movies job will send to
+// Neo4j, so one UNWIND can't balloon into an unbounded transaction.
+const maxImportRows = 100000
+
+// importHTTPClient fetches import URLs through dialImportConn, which
+// resolves and validates the destination at the moment of each TCP connect
+// rather than ahead of time. That's what makes it safe against both DNS
+// rebinding (the IP that answers is the IP that gets validated, with no gap
+// in between) and redirects to an internal address: http.Client redials for
+// every hop a 3xx response sends it through, so each hop is revalidated the
+// same way the original request was.
+var importHTTPClient = &http.Client{
+	Transport: &http.Transport{
+		DialContext: dialImportConn,
+	},
+}
+
+// validateImportURL rejects anything but a plain http(s) request with a
+// host, so a job can't be used to make the server issue requests with an
+// unsupported or empty scheme. Destination-address validation happens in
+// dialImportConn instead, where it can't be bypassed by a redirect or a
+// rebind between lookup and connect.
+func validateImportURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("parsing import url: %w", err)
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("unsupported import url scheme %q", parsed.Scheme)
+	}
+	if parsed.Hostname() == "" {
+		return fmt.Errorf("import url has no host")
+	}
+	return nil
+}
+
+// dialImportConn resolves addr's host itself (rather than trusting a
+// previous lookup) and only dials IPs that aren't loopback, link-local,
+// unspecified, or private, so an import job can't reach internal services
+// or cloud metadata endpoints (SSRF) - including via DNS rebinding or a
+// redirect to one of those addresses, since this runs on every connection
+// http.Client makes, not just the first.
+func dialImportConn(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	ips, err := net.DefaultResolver.LookupIP(ctx, "ip", host)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s: %w", host, err)
+	}
+
+	var dialer net.Dialer
+	var lastErr error
+	for _, ip := range ips {
+		if isDisallowedImportIP(ip) {
+			lastErr = fmt.Errorf("host %s resolves to a disallowed address %s", host, ip)
+			continue
+		}
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("host %s has no addresses to dial", host)
+	}
+	return nil, lastErr
+}
+
+func isDisallowedImportIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsPrivate()
+}
+
+// limitedReader returns an error from any Read made after limit bytes have
+// been read (even if the underlying body has nothing left), rather than
+// silently truncating like io.LimitReader would.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (lr *limitedReader) Read(p []byte) (int, error) {
+	if lr.remaining <= 0 {
+		return 0, fmt.Errorf("import response exceeds %d byte limit", maxImportBytes)
+	}
+	if int64(len(p)) > lr.remaining {
+		p = p[:lr.remaining]
+	}
+	n, err := lr.r.Read(p)
+	lr.remaining -= int64(n)
+	return n, err
+}
+
+// fetchRows downloads a movie dataset from rawURL and decodes it into rows
+// for the import Cypher, which reads each row's title, tagline and released.
+// JSON rows are passed on as decoded; CSV rows are keyed by the header
+// columns, with only the released column converted to an integer.
+func fetchRows(ctx context.Context, rawURL, format string) ([]map[string]interface{}, error) {
+	if err := validateImportURL(rawURL); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building request for %s: %w", rawURL, err)
+	}
+	resp, err := importHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching %s: %w", rawURL, err)
+	}
+	defer resp.Body.Close()
+	body := &limitedReader{r: resp.Body, remaining: maxImportBytes}
+
+	var rows []map[string]interface{}
+	switch format {
+	case "csv":
+		r := csv.NewReader(body)
+		records, err := r.ReadAll()
+		if err != nil || len(records) == 0 {
+			return nil, fmt.Errorf("reading csv from %s: %w", rawURL, err)
+		}
+		header := records[0]
+		rows = make([]map[string]interface{}, 0, len(records)-1)
+		for _, record := range records[1:] {
+			row := make(map[string]interface{}, len(header))
+			for i, col := range header {
+				if i >= len(record) {
+					continue
+				}
+				if col == "released" {
+					row[col], _ = strconv.ParseInt(record[i], 10, 64)
+				} else {
+					row[col] = record[i]
+				}
+			}
+			rows = append(rows, row)
+		}
+	default: // "json"
+		if err := json.NewDecoder(body).Decode(&rows); err != nil {
+			return nil, fmt.Errorf("decoding json from %s: %w", rawURL, err)
+		}
+	}
+
+	if len(rows) > maxImportRows {
+		return nil, fmt.Errorf("import from %s has %d rows, exceeding the %d row limit", rawURL, len(rows), maxImportRows)
+	}
+	return rows, nil
+}
@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Status is the lifecycle state of a Job.
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusRunning Status = "running"
+	StatusDone    Status = "done"
+	StatusFailed  Status = "failed"
+)
+
+// Type identifies which handler a Job should be dispatched to.
+type Type string
+
+const (
+	TypeImportMovies    Type = "import_movies"
+	TypeRecomputeCoStar Type = "recompute_costars"
+	TypePrecomputeGraph Type = "precompute_graph"
+)
+
+// Summary mirrors the subset of neo4j.ResultSummary.Counters() operators
+// care about when judging whether a long-running job did anything useful.
+type Summary struct {
+	NodesCreated         int           `json:"nodesCreated"`
+	RelationshipsCreated int           `json:"relationshipsCreated"`
+	Duration             time.Duration `json:"duration"`
+}
+
+// Job is a single unit of work on the queue. ClientKey, when non-empty,
+// makes enqueueing idempotent: re-enqueueing with the same key returns the
+// existing job instead of creating a duplicate.
+type Job struct {
+	ID        string          `json:"id"`
+	ClientKey string          `json:"clientKey,omitempty"`
+	Type      Type            `json:"type"`
+	Params    json.RawMessage `json:"params,omitempty"`
+	Status    Status          `json:"status"`
+	Error     string          `json:"error,omitempty"`
+	ResultURL string          `json:"resultUrl,omitempty"`
+	Summary   *Summary        `json:"summary,omitempty"`
+	Attempts  int             `json:"attempts"`
+	CreatedAt time.Time       `json:"createdAt"`
+	UpdatedAt time.Time       `json:"updatedAt"`
+}
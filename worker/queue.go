@@ -0,0 +1,195 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// staleLeaseAfter bounds how long a job may sit in "running" before
+// reapStale assumes the worker that claimed it died mid-handler (OOM,
+// kill -9, ...) and puts it back on the queue for another worker to try.
+const staleLeaseAfter = 5 * time.Minute
+
+// Queue is a durable, idempotent job queue backed by SQLite. A single table
+// holds every job; status transitions are plain UPDATEs, and reapStale
+// recovers jobs left behind by a worker that crashed mid-handler.
+type Queue struct {
+	db *sql.DB
+}
+
+// NewQueue opens (and if necessary creates) the SQLite-backed queue at path.
+func NewQueue(path string) (*Queue, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("opening job queue: %w", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS jobs (
+		id TEXT PRIMARY KEY,
+		client_key TEXT UNIQUE,
+		type TEXT NOT NULL,
+		params TEXT,
+		status TEXT NOT NULL,
+		error TEXT,
+		result_url TEXT,
+		summary TEXT,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL
+	)`); err != nil {
+		return nil, fmt.Errorf("creating jobs table: %w", err)
+	}
+	return &Queue{db: db}, nil
+}
+
+// Enqueue inserts a new job, or returns the existing one if clientKey has
+// already been submitted.
+func (q *Queue) Enqueue(ctx context.Context, clientKey string, jobType Type, params json.RawMessage) (*Job, error) {
+	if clientKey != "" {
+		if existing, err := q.getByClientKey(ctx, clientKey); err == nil {
+			return existing, nil
+		} else if err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
+	job := &Job{
+		ID:        uuid.NewString(),
+		ClientKey: clientKey,
+		Type:      jobType,
+		Params:    params,
+		Status:    StatusPending,
+	}
+	_, err := q.db.ExecContext(ctx, `INSERT INTO jobs
+		(id, client_key, type, params, status, attempts, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, 0, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)`,
+		job.ID, nullable(clientKey), string(job.Type), string(params), string(job.Status))
+	if err != nil {
+		return nil, fmt.Errorf("enqueuing job: %w", err)
+	}
+	return q.Get(ctx, job.ID)
+}
+
+// Get fetches a single job by id.
+func (q *Queue) Get(ctx context.Context, id string) (*Job, error) {
+	row := q.db.QueryRowContext(ctx, `SELECT id, client_key, type, params, status, error,
+		result_url, summary, attempts, created_at, updated_at FROM jobs WHERE id = ?`, id)
+	return scanJob(row)
+}
+
+func (q *Queue) getByClientKey(ctx context.Context, clientKey string) (*Job, error) {
+	row := q.db.QueryRowContext(ctx, `SELECT id, client_key, type, params, status, error,
+		result_url, summary, attempts, created_at, updated_at FROM jobs WHERE client_key = ?`, clientKey)
+	return scanJob(row)
+}
+
+// List returns every job, most recently created first.
+func (q *Queue) List(ctx context.Context) ([]*Job, error) {
+	rows, err := q.db.QueryContext(ctx, `SELECT id, client_key, type, params, status, error,
+		result_url, summary, attempts, created_at, updated_at FROM jobs ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var jobs []*Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+	return jobs, rows.Err()
+}
+
+// claimNext claims the oldest pending job with a single UPDATE...RETURNING
+// statement, or returns sql.ErrNoRows if the queue is empty. A plain
+// SELECT-then-UPDATE would let two Worker processes sharing the same
+// database both pick up the same row before either commits; folding the
+// selection into the UPDATE's subquery makes the claim atomic under
+// SQLite's single-writer lock.
+func (q *Queue) claimNext(ctx context.Context) (*Job, error) {
+	row := q.db.QueryRowContext(ctx, `UPDATE jobs SET status = ?, attempts = attempts + 1,
+		updated_at = CURRENT_TIMESTAMP
+		WHERE id = (SELECT id FROM jobs WHERE status = ? ORDER BY created_at ASC LIMIT 1)
+		RETURNING id, client_key, type, params, status, error, result_url, summary, attempts, created_at, updated_at`,
+		string(StatusRunning), string(StatusPending))
+	return scanJob(row)
+}
+
+// reapStale requeues jobs that have been "running" for longer than
+// staleLeaseAfter, on the assumption that whichever worker claimed them is
+// gone and never will finish them.
+func (q *Queue) reapStale(ctx context.Context) error {
+	// updated_at holds CURRENT_TIMESTAMP text, which is UTC, so the cutoff
+	// must be bound in UTC for the string comparison to be meaningful.
+	cutoff := time.Now().UTC().Add(-staleLeaseAfter)
+	_, err := q.db.ExecContext(ctx, `UPDATE jobs SET status = ?, updated_at = CURRENT_TIMESTAMP
+		WHERE status = ? AND updated_at < ?`,
+		string(StatusPending), string(StatusRunning), cutoff)
+	return err
+}
+
+func (q *Queue) finish(ctx context.Context, id string, summary *Summary, resultURL string, execErr error) error {
+	status := StatusDone
+	var errMsg string
+	if execErr != nil {
+		status = StatusFailed
+		errMsg = execErr.Error()
+	}
+	var summaryJSON []byte
+	if summary != nil {
+		var err error
+		if summaryJSON, err = json.Marshal(summary); err != nil {
+			return err
+		}
+	}
+	_, err := q.db.ExecContext(ctx, `UPDATE jobs SET status = ?, error = ?, result_url = ?,
+		summary = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		string(status), nullable(errMsg), nullable(resultURL), nullable(string(summaryJSON)), id)
+	return err
+}
+
+// requeue resets a failed job back to pending so the retry loop picks it up.
+func (q *Queue) requeue(ctx context.Context, id string) error {
+	_, err := q.db.ExecContext(ctx, `UPDATE jobs SET status = ?, updated_at = CURRENT_TIMESTAMP WHERE id = ?`,
+		string(StatusPending), id)
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanJob(s scanner) (*Job, error) {
+	var job Job
+	var clientKey, errMsg, resultURL, summaryJSON, params sql.NullString
+	if err := s.Scan(&job.ID, &clientKey, &job.Type, &params, &job.Status, &errMsg,
+		&resultURL, &summaryJSON, &job.Attempts, &job.CreatedAt, &job.UpdatedAt); err != nil {
+		return nil, err
+	}
+	job.ClientKey = clientKey.String
+	job.Error = errMsg.String
+	job.ResultURL = resultURL.String
+	job.Params = json.RawMessage(params.String)
+	if summaryJSON.Valid && summaryJSON.String != "" {
+		var summary Summary
+		if err := json.Unmarshal([]byte(summaryJSON.String), &summary); err == nil {
+			job.Summary = &summary
+		}
+	}
+	return &job, nil
+}
+
+func nullable(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
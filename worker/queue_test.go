@@ -0,0 +1,142 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestQueue(t *testing.T) *Queue {
+	t.Helper()
+	q, err := NewQueue(":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Every connection to ":memory:" opens its own empty database, so the
+	// pool must never open a second one.
+	q.db.SetMaxOpenConns(1)
+	t.Cleanup(func() { _ = q.db.Close() })
+	return q
+}
+
+func TestQueue_EnqueueIsIdempotent(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+
+	first, err := q.Enqueue(ctx, "client-key", TypeImportMovies, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := q.Enqueue(ctx, "client-key", TypeImportMovies, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != second.ID {
+		t.Fatalf("expected re-enqueueing the same clientKey to return the same job, got %s and %s", first.ID, second.ID)
+	}
+
+	jobs, err := q.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(jobs) != 1 {
+		t.Fatalf("expected exactly one job, got %d", len(jobs))
+	}
+}
+
+func TestQueue_ClaimNextIsAtomicUnderConcurrentClaimers(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+
+	const jobCount = 20
+	for i := 0; i < jobCount; i++ {
+		if _, err := q.Enqueue(ctx, "", TypeImportMovies, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		claimed = make(map[string]int)
+	)
+	for i := 0; i < jobCount; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job, err := q.claimNext(ctx)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			claimed[job.ID]++
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(claimed) != jobCount {
+		t.Fatalf("expected %d distinct jobs claimed, got %d", jobCount, len(claimed))
+	}
+	for id, count := range claimed {
+		if count != 1 {
+			t.Errorf("job %s was claimed %d times", id, count)
+		}
+	}
+}
+
+func TestQueue_ReapStaleRequeuesAbandonedRunningJobs(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+
+	job, err := q.Enqueue(ctx, "", TypeImportMovies, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.claimNext(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	stale := time.Now().UTC().Add(-2 * staleLeaseAfter)
+	if _, err := q.db.ExecContext(ctx, `UPDATE jobs SET updated_at = ? WHERE id = ?`, stale, job.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.reapStale(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := q.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusPending {
+		t.Fatalf("expected reapStale to requeue the abandoned job, got status %q", got.Status)
+	}
+}
+
+func TestQueue_ReapStaleLeavesFreshRunningJobsAlone(t *testing.T) {
+	q := newTestQueue(t)
+	ctx := context.Background()
+
+	job, err := q.Enqueue(ctx, "", TypeImportMovies, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := q.claimNext(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := q.reapStale(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := q.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusRunning {
+		t.Fatalf("expected a job still within its lease to stay running, got status %q", got.Status)
+	}
+}
@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// maxAttempts bounds the exponential backoff retry: after this many failed
+// attempts a job is left in StatusFailed instead of being requeued.
+const maxAttempts = 5
+
+// Handler executes one job against the graph and reports what it did.
+type Handler func(ctx context.Context, driver neo4j.DriverWithContext, database string, job *Job) (summary *Summary, resultURL string, err error)
+
+// Worker polls a Queue and dispatches jobs to registered Handlers. It is the
+// long-running counterpart to the request handlers in server.go,
+// letting heavy imports and recomputes run off the HTTP request path.
+type Worker struct {
+	queue     *Queue
+	driver    neo4j.DriverWithContext
+	database  string
+	handlers  map[Type]Handler
+	pollEvery time.Duration
+	reapEvery time.Duration
+
+	// afterFunc defaults to time.AfterFunc; tests override it to run the
+	// backoff callback immediately instead of waiting out the real delay.
+	afterFunc func(d time.Duration, f func()) *time.Timer
+}
+
+// New builds a Worker. handlers maps each supported Type to the function
+// that performs it; an unregistered Type fails the job immediately.
+func New(queue *Queue, driver neo4j.DriverWithContext, database string, handlers map[Type]Handler) *Worker {
+	return &Worker{
+		queue:     queue,
+		driver:    driver,
+		database:  database,
+		handlers:  handlers,
+		pollEvery: time.Second,
+		reapEvery: time.Minute,
+		afterFunc: time.AfterFunc,
+	}
+}
+
+// Run polls for work until ctx is cancelled. Alongside the poll loop it
+// periodically reaps jobs left "running" by a worker that crashed
+// mid-handler, so a killed process can't strand a job forever.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.pollEvery)
+	defer ticker.Stop()
+	reapTicker := time.NewTicker(w.reapEvery)
+	defer reapTicker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		case <-reapTicker.C:
+			if err := w.queue.reapStale(ctx); err != nil {
+				log.Println("error reaping stale jobs:", err)
+			}
+		}
+	}
+}
+
+func (w *Worker) runOnce(ctx context.Context) {
+	job, err := w.queue.claimNext(ctx)
+	if err == sql.ErrNoRows {
+		return
+	}
+	if err != nil {
+		log.Println("error claiming job:", err)
+		return
+	}
+
+	handler, ok := w.handlers[job.Type]
+	if !ok {
+		_ = w.queue.finish(ctx, job.ID, nil, "", errUnknownType(job.Type))
+		return
+	}
+
+	start := time.Now()
+	summary, resultURL, execErr := handler(ctx, w.driver, w.database, job)
+	if summary != nil {
+		summary.Duration = time.Since(start)
+	}
+
+	if execErr != nil && job.Attempts < maxAttempts {
+		log.Printf("job %s failed (attempt %d/%d), backing off: %v", job.ID, job.Attempts, maxAttempts, execErr)
+		w.backoffThenRequeue(job.ID, job.Attempts)
+		return
+	}
+	if err := w.queue.finish(ctx, job.ID, summary, resultURL, execErr); err != nil {
+		log.Println("error finishing job:", err)
+	}
+}
+
+// backoffThenRequeue schedules the job to be put back on the queue after an
+// exponential delay and returns without waiting, so a misbehaving import
+// doesn't spin the poll loop.
+func (w *Worker) backoffThenRequeue(id string, attempts int) {
+	delay := time.Duration(1<<uint(attempts)) * time.Second
+	w.afterFunc(delay, func() {
+		if err := w.queue.requeue(context.Background(), id); err != nil {
+			log.Println("error requeuing job:", err)
+		}
+	})
+}
+
+type errUnknownType Type
+
+func (e errUnknownType) Error() string {
+	return "no handler registered for job type " + string(e)
+}
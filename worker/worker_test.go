@@ -0,0 +1,135 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+// immediateAfterFunc runs f synchronously instead of waiting out d, so tests
+// exercise the backoff-then-requeue path without sleeping for real delays.
+func immediateAfterFunc(d time.Duration, f func()) *time.Timer {
+	f()
+	return time.NewTimer(0)
+}
+
+func newTestWorker(t *testing.T, handlers map[Type]Handler) (*Worker, *Queue) {
+	t.Helper()
+	q := newTestQueue(t)
+	w := New(q, nil, "", handlers)
+	w.afterFunc = immediateAfterFunc
+	return w, q
+}
+
+func TestWorker_RunOnceFinishesASuccessfulJob(t *testing.T) {
+	const jobType Type = "succeeds"
+	w, q := newTestWorker(t, map[Type]Handler{
+		jobType: func(ctx context.Context, driver neo4j.DriverWithContext, database string, job *Job) (*Summary, string, error) {
+			return &Summary{NodesCreated: 1}, "", nil
+		},
+	})
+	ctx := context.Background()
+	job, err := q.Enqueue(ctx, "", jobType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.runOnce(ctx)
+
+	got, err := q.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusDone {
+		t.Fatalf("expected job to finish done, got status %q", got.Status)
+	}
+	if got.Summary == nil || got.Summary.NodesCreated != 1 {
+		t.Fatalf("expected the handler's summary to be persisted, got %+v", got.Summary)
+	}
+}
+
+func TestWorker_RunOnceRequeuesAFailedJobUnderMaxAttempts(t *testing.T) {
+	const jobType Type = "fails"
+	failWith := errors.New("boom")
+	w, q := newTestWorker(t, map[Type]Handler{
+		jobType: func(ctx context.Context, driver neo4j.DriverWithContext, database string, job *Job) (*Summary, string, error) {
+			return nil, "", failWith
+		},
+	})
+	ctx := context.Background()
+	job, err := q.Enqueue(ctx, "", jobType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.runOnce(ctx)
+
+	got, err := q.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusPending {
+		t.Fatalf("expected a job under maxAttempts to be requeued as pending, got status %q", got.Status)
+	}
+	if got.Error != "" {
+		t.Fatalf("expected a requeued job not to record an error yet, got %q", got.Error)
+	}
+}
+
+func TestWorker_RunOnceFailsAJobAfterMaxAttempts(t *testing.T) {
+	const jobType Type = "always-fails"
+	failWith := errors.New("boom")
+	w, q := newTestWorker(t, map[Type]Handler{
+		jobType: func(ctx context.Context, driver neo4j.DriverWithContext, database string, job *Job) (*Summary, string, error) {
+			return nil, "", failWith
+		},
+	})
+	ctx := context.Background()
+	job, err := q.Enqueue(ctx, "", jobType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < maxAttempts; i++ {
+		w.runOnce(ctx)
+	}
+
+	got, err := q.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusFailed {
+		t.Fatalf("expected the job to be failed after %d attempts, got status %q", maxAttempts, got.Status)
+	}
+	if got.Error != failWith.Error() {
+		t.Fatalf("expected the handler's error to be recorded, got %q", got.Error)
+	}
+}
+
+func TestWorker_RunOnceFailsAJobWithNoRegisteredHandler(t *testing.T) {
+	const jobType Type = "unregistered"
+	w, q := newTestWorker(t, map[Type]Handler{})
+	ctx := context.Background()
+	job, err := q.Enqueue(ctx, "", jobType, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w.runOnce(ctx)
+
+	got, err := q.Get(ctx, job.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != StatusFailed {
+		t.Fatalf("expected an unregistered job type to fail immediately, got status %q", got.Status)
+	}
+}
+
+func TestWorker_RunOnceIsANoOpWhenQueueIsEmpty(t *testing.T) {
+	w, _ := newTestWorker(t, map[Type]Handler{})
+	w.runOnce(context.Background())
+}